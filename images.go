@@ -1,57 +1,337 @@
 package gophercloud
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers crypto.SHA256 for (crypto.Hash).New()
+	_ "crypto/sha512" // registers crypto.SHA384/SHA512 for (crypto.Hash).New()
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"github.com/racker/perigee"
+	"hash"
 	"io"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// imagePollInterval is how often WaitForImageStatus re-checks image status
+// while waiting for an asynchronous import to finish.
+const imagePollInterval = 2 * time.Second
+
+// imagesBaseURL returns the unfiltered, unpaginated list/details endpoint
+// for this provider's Glance (or Glance-compatible v1) API version.
+func (gsp *genericServersProvider) imagesBaseURL() string {
+	if strings.HasSuffix(gsp.endpoint, "v1") {
+		return gsp.endpoint + "/images/details"
+	}
+	return gsp.endpoint + "/images"
+}
+
+// ListImagesOpts filters, sorts, and paginates a Glance v2 image listing.
+// All fields are optional; the zero value lists every image the caller can
+// see, in whatever order Glance defaults to.
+//
+// The Tags field is repeated as a separate tag= query parameter per value,
+// matching Glance's AND-of-tags semantics.
+//
+// The CreatedAtOp and UpdatedAtOp fields select a comparator ("gt", "lt",
+// "gte", "lte", "neq", "eq") applied to CreatedAt/UpdatedAt; left blank, the
+// timestamp is matched for equality.
+//
+// Marker and Limit drive pagination directly; most callers should instead
+// use EachImagePage and leave Marker unset, letting the "next" link in each
+// response page drive iteration.
+type ListImagesOpts struct {
+	Visibility   string
+	Owner        string
+	Status       string
+	Tags         []string
+	Name         string
+	MemberStatus string
+	CreatedAtOp  string
+	CreatedAt    string
+	UpdatedAtOp  string
+	UpdatedAt    string
+	SortKey      string
+	SortDir      string
+	Marker       string
+	Limit        int
+}
+
+// query renders opts as a URL query string (including the leading "?"), or
+// the empty string if no fields were set.
+func (opts ListImagesOpts) query() string {
+	v := url.Values{}
+
+	if opts.Visibility != "" {
+		v.Set("visibility", opts.Visibility)
+	}
+	if opts.Owner != "" {
+		v.Set("owner", opts.Owner)
+	}
+	if opts.Status != "" {
+		v.Set("status", opts.Status)
+	}
+	for _, tag := range opts.Tags {
+		v.Add("tag", tag)
+	}
+	if opts.Name != "" {
+		v.Set("name", opts.Name)
+	}
+	if opts.MemberStatus != "" {
+		v.Set("member_status", opts.MemberStatus)
+	}
+	if opts.CreatedAt != "" {
+		v.Set("created_at", timeComparator(opts.CreatedAtOp, opts.CreatedAt))
+	}
+	if opts.UpdatedAt != "" {
+		v.Set("updated_at", timeComparator(opts.UpdatedAtOp, opts.UpdatedAt))
+	}
+	if opts.SortKey != "" {
+		v.Set("sort_key", opts.SortKey)
+	}
+	if opts.SortDir != "" {
+		v.Set("sort_dir", opts.SortDir)
+	}
+	if opts.Marker != "" {
+		v.Set("marker", opts.Marker)
+	}
+	if opts.Limit > 0 {
+		v.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	if encoded := v.Encode(); encoded != "" {
+		return "?" + encoded
+	}
+	return ""
+}
+
+// timeComparator formats a time filter as Glance expects: "gt:value" when a
+// comparator is given, or the bare value for an equality match.
+func timeComparator(op, value string) string {
+	if op == "" {
+		return value
+	}
+	return op + ":" + value
+}
+
+// imagesPage is the envelope Glance v2 wraps each page of a list/details
+// response in.
+type imagesPage struct {
+	Images []Image `json:"images"`
+	Next   string  `json:"next"`
+}
+
+// nextPageURL resolves the "next" link of an images page, which Glance
+// returns relative to the API root, against this provider's endpoint.
+func (gsp *genericServersProvider) nextPageURL(next string) (string, error) {
+	return resolveNextPageURL(gsp.endpoint, next)
+}
+
+// resolveNextPageURL joins a Glance "next" link (which may be a bare path
+// like "/v2/images?marker=..." or, less commonly, an absolute URL) against
+// endpoint, the base URL images were originally listed from.
+func resolveNextPageURL(endpoint string, next string) (string, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// EachImagePage lists images matching opts, invoking handler once per page
+// of results. Handler returns false to stop early; EachImagePage otherwise
+// follows the "next" link until Glance reports no further pages.
+func (gsp *genericServersProvider) EachImagePage(opts ListImagesOpts, handler func(page []Image) (bool, error)) error {
+	nextURL := gsp.imagesBaseURL() + opts.query()
+
+	for nextURL != "" {
+		// Reauth is scoped to this single page GET, not the loop as a
+		// whole: WithReauth retries its closure on a 401, and retrying
+		// the whole loop would re-deliver pages already passed to
+		// handler.
+		var page imagesPage
+		err := gsp.context.WithReauth(gsp.access, func() error {
+			return perigee.Get(nextURL, perigee.Options{
+				CustomClient: gsp.context.httpClient,
+				Results:      &page,
+				MoreHeaders: map[string]string{
+					"X-Auth-Token": gsp.access.AuthToken(),
+				},
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		more, err := handler(page.Images)
+		if err != nil {
+			return err
+		}
+		if !more || page.Next == "" {
+			return nil
+		}
+
+		nextURL, err = gsp.nextPageURL(page.Next)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // See the CloudImagesProvider interface for details.
 func (gsp *genericServersProvider) ListImages() ([]Image, error) {
 	var is []Image
 
+	err := gsp.EachImagePage(ListImagesOpts{}, func(page []Image) (bool, error) {
+		is = append(is, page...)
+		return true, nil
+	})
+
+	return is, err
+}
+
+// ImageById fetches a single image. Glance v2's GET /v2/images/{id} returns
+// the image object unwrapped at the top level (the same shape UpdateImage's
+// PATCH response has), not wrapped in an "image" envelope key.
+func (gsp *genericServersProvider) ImageById(id string) (*Image, error) {
+	var is Image
+
 	err := gsp.context.WithReauth(gsp.access, func() error {
-		var url string
-		if strings.HasSuffix(gsp.endpoint, "v1") {
-			url = gsp.endpoint + "/images/details"
-		} else {
-			url = gsp.endpoint + "/images"
-		}
+		url := gsp.endpoint + "/images/" + id
 		return perigee.Get(url, perigee.Options{
 			CustomClient: gsp.context.httpClient,
-			Results:      &struct{ Images *[]Image }{&is},
+			Results:      &is,
 			MoreHeaders: map[string]string{
 				"X-Auth-Token": gsp.access.AuthToken(),
 			},
 		})
 	})
-	return is, err
+	if err != nil {
+		return nil, err
+	}
+	return &is, nil
 }
 
-func (gsp *genericServersProvider) ImageById(id string) (*Image, error) {
-	var is *Image
+// ImagePatchOp is a single RFC 6902 JSON Patch operation, as accepted by
+// the Glance v2 PATCH /v2/images/{id} endpoint. Op must be "add",
+// "replace", or "remove"; Value is omitted from the wire body for
+// "remove" ops.
+type ImagePatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// UpdateImage applies ops to the image identified by imageId via Glance's
+// JSON Patch endpoint and returns the resulting image.
+func (gsp *genericServersProvider) UpdateImage(imageId string, ops []ImagePatchOp) (*Image, error) {
+	var updated Image
 
 	err := gsp.context.WithReauth(gsp.access, func() error {
-		url := gsp.endpoint + "/images/" + id
-		return perigee.Get(url, perigee.Options{
+		url := gsp.endpoint + "/images/" + imageId
+		_, err := perigee.Request("PATCH", url, perigee.Options{
+			ReqBody:      &ops,
+			ContentType:  "application/openstack-images-v2.1-json-patch",
 			CustomClient: gsp.context.httpClient,
-			Results:      &struct{ Image **Image }{&is},
+			Results:      &updated,
 			MoreHeaders: map[string]string{
 				"X-Auth-Token": gsp.access.AuthToken(),
 			},
+			OkCodes: []int{200},
 		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// AddImageTag attaches tag to the image identified by imageId. This uses
+// the dedicated tags endpoint rather than UpdateImage since Glance treats
+// it as idempotent (no error if the tag is already present).
+func (gsp *genericServersProvider) AddImageTag(imageId string, tag string) error {
+	return gsp.context.WithReauth(gsp.access, func() error {
+		url := gsp.endpoint + "/images/" + imageId + "/tags/" + url.PathEscape(tag)
+		_, err := perigee.Request("PUT", url, perigee.Options{
+			CustomClient: gsp.context.httpClient,
+			MoreHeaders: map[string]string{
+				"X-Auth-Token": gsp.access.AuthToken(),
+			},
+			OkCodes: []int{204},
+		})
+		return err
+	})
+}
+
+// RemoveImageTag detaches tag from the image identified by imageId. It is
+// a no-op, per Glance, if the tag is not present.
+func (gsp *genericServersProvider) RemoveImageTag(imageId string, tag string) error {
+	return gsp.context.WithReauth(gsp.access, func() error {
+		url := gsp.endpoint + "/images/" + imageId + "/tags/" + url.PathEscape(tag)
+		_, err := perigee.Request("DELETE", url, perigee.Options{
+			CustomClient: gsp.context.httpClient,
+			MoreHeaders: map[string]string{
+				"X-Auth-Token": gsp.access.AuthToken(),
+			},
+			OkCodes: []int{204},
+		})
+		return err
+	})
+}
+
+// SetImageVisibility changes the visibility of the image identified by
+// imageId (typically "public" or "private") and returns the updated image.
+func (gsp *genericServersProvider) SetImageVisibility(imageId string, visibility string) (*Image, error) {
+	return gsp.UpdateImage(imageId, []ImagePatchOp{
+		{Op: "replace", Path: "/visibility", Value: visibility},
 	})
-	return is, err
 }
 
 func (gsp *genericServersProvider) CreateNewImage(ni NewImage) (string, error) {
+	var reqBody interface{} = &struct {
+		*NewImage `json:""`
+	}{&ni}
+
+	// A signature doesn't have a json tag on NewImage since it maps to
+	// four flat properties rather than a nested object; fold it into the
+	// request body by hand when the caller set one.
+	if ni.Signature != nil {
+		data, err := json.Marshal(reqBody)
+		if err != nil {
+			return "", err
+		}
+		var merged map[string]interface{}
+		if err := json.Unmarshal(data, &merged); err != nil {
+			return "", err
+		}
+		for key, value := range imageSignatureProperties(ni.Signature) {
+			merged[key] = value
+		}
+		reqBody = merged
+	}
+
 	response, err := gsp.context.ResponseWithReauth(gsp.access, func() (*perigee.Response, error) {
 		url := gsp.endpoint + "/images"
 		return perigee.Request("POST", url, perigee.Options{
-			ReqBody: &struct {
-				*NewImage `json:""`
-			}{&ni},
+			ReqBody:      reqBody,
 			CustomClient: gsp.context.httpClient,
 			MoreHeaders: map[string]string{
 				"X-Auth-Token": gsp.access.AuthToken(),
@@ -74,83 +354,619 @@ func (gsp *genericServersProvider) CreateNewImage(ni NewImage) (string, error) {
 	return locationArr[len(locationArr)-1], err
 }
 
-// Stream a file as mime/multipart (application/octet-stream). The gist is
-// to stream from a file, into (the write side of) a pipe, copy the file
-// into the pipe, and then close the relevant file/pipe objects. This usually
-// gets run asynchronously so HTTP requests can read from the read side
-// of the pipe for the octet-stream. Any errors get set in ppError.
-// This code was adapted from:
-//    https://github.com/gebi/go-fileupload-example/blob/master/main.go
-func streamFile(readFrom *os.File,
-	readFromPath string,
-	writePipe *io.PipeWriter,
-	formLabel string,
-	ppErr **error) {
-
-	// Assure the file closes when exiting this function. Note that the
-	// caller should not defer this close since this function likely runs
-	// asynchronously.
-	defer readFrom.Close()
-
-	// Assure the write side of the pipe closes when exiting this function.
-	defer writePipe.Close()
-
-	// copy from the file to stream into the multipart.
-	_, err := io.Copy(writePipe, readFrom)
-	if err != nil {
-		*ppErr = &err
-		return
+// ImportMethod identifies which Glance v2 interoperable image import method
+// an ImportSpec should use.
+type ImportMethod string
+
+const (
+	ImportMethodWebDownload ImportMethod = "web-download"
+	ImportMethodCopyImage   ImportMethod = "copy-image"
+
+	// glance-direct is deliberately not exposed here: it requires a
+	// staged PUT to /v2/images/{id}/stage before POSTing /import, and
+	// this package has no staging helper yet. Add ImportMethodGlanceDirect
+	// back once that exists; until then, selecting it would just import
+	// an image with no staged data.
+)
+
+// ImportSpec describes how Glance should populate an already-created image
+// record via the interoperable image import workflow.
+//
+// The Method field selects web-download or copy-image. glance-direct is
+// not yet supported since it requires a staging upload this package
+// doesn't implement; see the ImportMethod constants.
+//
+// The URI field is required for web-download. It gives the remote location
+// Glance should fetch the image bytes from.
+//
+// The Stores field restricts the import to the named backend stores. If
+// empty, Glance imports into its default store unless AllStores is set.
+//
+// The AllStores field requests that the image be imported into every store
+// configured on the Glance node, superseding Stores.
+//
+// The MD5 and SHA512 fields are optional checksums. When provided, Glance
+// verifies the downloaded bytes against them before marking the image
+// active, failing the import otherwise.
+type ImportSpec struct {
+	Method    ImportMethod
+	URI       string
+	Stores    []string
+	AllStores bool
+	MD5       string
+	SHA512    string
+}
+
+// ImportImage kicks off an asynchronous Glance v2 image import for the
+// already-created image identified by imageId. Use WaitForImageStatus to
+// poll until the import completes.
+func (gsp *genericServersProvider) ImportImage(imageId string, spec ImportSpec) error {
+	method := struct {
+		Name   ImportMethod `json:"name"`
+		URI    string       `json:"uri,omitempty"`
+		MD5    string       `json:"md5,omitempty"`
+		SHA512 string       `json:"sha512,omitempty"`
+	}{
+		Name:   spec.Method,
+		URI:    spec.URI,
+		MD5:    spec.MD5,
+		SHA512: spec.SHA512,
+	}
+
+	body := struct {
+		Method    interface{} `json:"method"`
+		Stores    []string    `json:"stores,omitempty"`
+		AllStores bool        `json:"all_stores,omitempty"`
+	}{
+		Method:    method,
+		Stores:    spec.Stores,
+		AllStores: spec.AllStores,
 	}
 
-	*ppErr = nil
+	return gsp.context.WithReauth(gsp.access, func() error {
+		url := gsp.endpoint + "/images/" + imageId + "/import"
+		_, err := perigee.Request("POST", url, perigee.Options{
+			ReqBody:      &body,
+			CustomClient: gsp.context.httpClient,
+			MoreHeaders: map[string]string{
+				"X-Auth-Token": gsp.access.AuthToken(),
+			},
+			OkCodes: []int{202},
+		})
+		return err
+	})
 }
 
-func (gsp *genericServersProvider) UploadImageFile(imageId string,
-	imagePath string) error {
+// WaitForImageStatus polls the image identified by imageId until its status
+// equals status, a terminal failure status (killed, deactivated) is
+// observed, or timeout elapses. It returns the last-seen Image in all
+// cases where one could be fetched.
+func (gsp *genericServersProvider) WaitForImageStatus(imageId string, status string, timeout time.Duration) (*Image, error) {
+	deadline := time.Now().Add(timeout)
 
-	_, err := gsp.context.ResponseWithReauth(gsp.access,
-		func() (*perigee.Response, error) {
-			url := gsp.endpoint + "/images/" + imageId + "/file"
+	for {
+		image, err := gsp.ImageById(imageId)
+		if err != nil {
+			return nil, err
+		}
+		if image == nil {
+			return nil, fmt.Errorf("gophercloud: image %s not found", imageId)
+		}
 
-			// Get the file size for later http header setting.
-			var fileSize int64
-			fi, err := os.Stat(imagePath)
-			if err != nil {
-				return nil, err
+		if image.Status == status {
+			return image, nil
+		}
+
+		if image.Status == "killed" || image.Status == "deactivated" {
+			return image, fmt.Errorf("image %s reached terminal status %q while waiting for %q", imageId, image.Status, status)
+		}
+
+		if time.Now().After(deadline) {
+			return image, fmt.Errorf("timed out waiting for image %s to reach status %q (last seen %q)", imageId, status, image.Status)
+		}
+
+		time.Sleep(imagePollInterval)
+	}
+}
+
+// ChecksumAlgorithm selects the hash UploadImageFile runs over the file
+// while it streams, for comparison against the checksum Glance reports
+// back after the upload completes. ChecksumMD5 hashes with MD5;
+// ChecksumSHA256 and ChecksumSHA512 both hash with whichever algorithm the
+// image's os_hash_algo property names (see resolveUploadChecksum).
+type ChecksumAlgorithm string
+
+const (
+	ChecksumNone   ChecksumAlgorithm = ""
+	ChecksumMD5    ChecksumAlgorithm = "md5"
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+	ChecksumSHA512 ChecksumAlgorithm = "sha512"
+)
+
+// ErrChecksumMismatch is returned by UploadImageFile when the checksum
+// Glance reports for the stored image does not match the checksum computed
+// locally while streaming the upload.
+var ErrChecksumMismatch = errors.New("gophercloud: uploaded image checksum does not match Glance")
+
+// UploadImageFileOpts controls the streaming, retry, and verification
+// behavior of UploadImageFile. The zero value uploads the file in one
+// shot, with no progress reporting, retries, or checksum verification.
+type UploadImageFileOpts struct {
+	// Progress, if set, is invoked with the number of bytes sent so far
+	// and the total file size, at most once per ChunkSize bytes, plus
+	// once more for a final partial chunk.
+	Progress func(bytesSent, totalBytes int64)
+
+	// Checksum selects a hash to compute while streaming and verify
+	// against Glance's reported checksum once the upload succeeds.
+	Checksum ChecksumAlgorithm
+
+	// MaxRetries is how many additional attempts to make after a
+	// retryable failure (connection reset, 408, 429, 5xx).
+	MaxRetries int
+
+	// RetryBackoff is how long to sleep between retry attempts.
+	RetryBackoff time.Duration
+
+	// ChunkSize controls how often Progress is called. It has no effect
+	// on the wire format of the upload.
+	ChunkSize int64
+}
+
+const (
+	defaultUploadChunkSize    = 1 << 20 // 1 MiB
+	defaultUploadRetryBackoff = time.Second
+)
+
+// osHashAlgoToHash maps Glance's os_hash_algo property (the hash the
+// deployment actually computes os_hash_value with, e.g. "sha256" or the
+// common default "sha512") to the matching crypto.Hash.
+func osHashAlgoToHash(name string) (crypto.Hash, error) {
+	switch name {
+	case "sha256":
+		return crypto.SHA256, nil
+	case "sha512":
+		return crypto.SHA512, nil
+	case "sha1":
+		return crypto.SHA1, nil
+	default:
+		return 0, fmt.Errorf("gophercloud: unsupported os_hash_algo %q", name)
+	}
+}
+
+// resolveUploadChecksum picks the hash constructor and Image.Properties
+// field UploadImageFile should verify against for the requested algorithm.
+//
+// For ChecksumSHA256/ChecksumSHA512 this deliberately ignores the exact
+// variant requested and instead asks Glance which algorithm it actually
+// computes os_hash_value with via the image's os_hash_algo property: that
+// is a deployment-wide setting (sha512 by default) independent of what the
+// caller asked for, and hashing with the wrong one would never match.
+func (gsp *genericServersProvider) resolveUploadChecksum(imageId string, algo ChecksumAlgorithm) (newHasher func() hash.Hash, remoteField string, err error) {
+	switch algo {
+	case ChecksumNone:
+		return nil, "", nil
+	case ChecksumMD5:
+		return md5.New, "checksum", nil
+	case ChecksumSHA256, ChecksumSHA512:
+		image, err := gsp.ImageById(imageId)
+		if err != nil {
+			return nil, "", err
+		}
+		if image == nil {
+			return nil, "", fmt.Errorf("gophercloud: image %s not found", imageId)
+		}
+		osHashAlgo, _ := image.Properties["os_hash_algo"].(string)
+		hashFunc, err := osHashAlgoToHash(osHashAlgo)
+		if err != nil {
+			return nil, "", err
+		}
+		return hashFunc.New, "os_hash_value", nil
+	default:
+		return nil, "", fmt.Errorf("gophercloud: unsupported checksum algorithm %q", algo)
+	}
+}
+
+// uploadProgressReader wraps an io.Reader, calling onChunk with the
+// cumulative number of bytes read after every chunkSize bytes (and on the
+// final short read).
+type uploadProgressReader struct {
+	r         io.Reader
+	chunkSize int64
+	onChunk   func(total int64)
+	read      int64
+	sinceLast int64
+}
+
+func (pr *uploadProgressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.read += int64(n)
+	pr.sinceLast += int64(n)
+	if pr.onChunk != nil && pr.sinceLast > 0 && (pr.sinceLast >= pr.chunkSize || err != nil) {
+		pr.onChunk(pr.read)
+		pr.sinceLast = 0
+	}
+	return n, err
+}
+
+// streamImageFile copies src into writePipe, closing writePipe with
+// whatever error (including nil) io.Copy returns so the reader side of the
+// pipe observes it directly rather than through a side channel.
+func streamImageFile(src io.Reader, writePipe *io.PipeWriter) {
+	_, err := io.Copy(writePipe, src)
+	writePipe.CloseWithError(err)
+}
+
+func isRetryableUploadError(response *perigee.Response, err error) bool {
+	if response == nil || response.HttpResponse == nil {
+		// Connection-level failure (reset, timeout, refused, ...).
+		return true
+	}
+	code := response.HttpResponse.StatusCode
+	return code == 408 || code == 429 || (code >= 500 && code <= 599)
+}
+
+// UploadImageFile streams the file at imagePath to the image identified by
+// imageId. See UploadImageFileOpts for progress reporting, retry, and
+// checksum verification knobs; the zero value is a plain single-shot
+// upload.
+func (gsp *genericServersProvider) UploadImageFile(imageId string, imagePath string, opts UploadImageFileOpts) error {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultUploadChunkSize
+	}
+	retryBackoff := opts.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = defaultUploadRetryBackoff
+	}
+
+	fi, err := os.Stat(imagePath)
+	if err != nil {
+		return err
+	}
+	totalSize := fi.Size()
+
+	newHasher, remoteField, err := gsp.resolveUploadChecksum(imageId, opts.Checksum)
+	if err != nil {
+		return err
+	}
+
+	var hasher hash.Hash
+	if newHasher != nil {
+		hasher = newHasher()
+	}
+
+	var (
+		offset        int64
+		acceptsRanges bool
+	)
+
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(retryBackoff)
+		}
+
+		response, sent, err := gsp.putImageFileOnce(imageId, imagePath, offset, totalSize, hasher, chunkSize, opts.Progress, &acceptsRanges)
+		if err == nil {
+			break
+		}
+
+		if attempt == opts.MaxRetries || !isRetryableUploadError(response, err) {
+			return err
+		}
+
+		if acceptsRanges {
+			offset += sent
+		} else {
+			offset = 0
+			if newHasher != nil {
+				hasher = newHasher()
 			}
-			fileSize = fi.Size()
+		}
+	}
 
-			// Open the file to stream as multipart/octet-stream, but do not
-			// defer its close here since it must remain open during the
-			// streaming operation and the streamer will close it.
-			inFile, err := os.Open(imagePath)
-			if err != nil {
-				return nil, err
+	if opts.Checksum == ChecksumNone {
+		return nil
+	}
+
+	localDigest := fmt.Sprintf("%x", hasher.Sum(nil))
+
+	image, err := gsp.ImageById(imageId)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return fmt.Errorf("gophercloud: image %s not found", imageId)
+	}
+
+	return verifyUploadChecksum(image.Properties, remoteField, opts.Checksum, localDigest)
+}
+
+// verifyUploadChecksum compares localDigest against properties[remoteField]
+// (the Image.Properties entry Glance reports the server-side digest
+// under), returning ErrChecksumMismatch on a mismatch.
+func verifyUploadChecksum(properties map[string]interface{}, remoteField string, algo ChecksumAlgorithm, localDigest string) error {
+	remoteDigest, _ := properties[remoteField].(string)
+	if remoteDigest == "" {
+		return fmt.Errorf("gophercloud: image has no %s to verify the upload against", remoteField)
+	}
+	if !strings.EqualFold(remoteDigest, localDigest) {
+		return fmt.Errorf("%w: local %s=%s, Glance reported %s=%s", ErrChecksumMismatch, algo, localDigest, remoteField, remoteDigest)
+	}
+	return nil
+}
+
+// putImageFileOnce issues a single PUT of imagePath (from offset onward)
+// to the image file endpoint, returning the response, the number of bytes
+// this attempt streamed, and the error. It sets *acceptsRanges to whether
+// the endpoint advertised Accept-Ranges support.
+func (gsp *genericServersProvider) putImageFileOnce(imageId string, imagePath string, offset int64, totalSize int64, hasher hash.Hash, chunkSize int64, progress func(sent, total int64), acceptsRanges *bool) (*perigee.Response, int64, error) {
+	inFile, err := os.Open(imagePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset > 0 {
+		if _, err := inFile.Seek(offset, io.SeekStart); err != nil {
+			inFile.Close()
+			return nil, 0, err
+		}
+	}
+
+	counter := &uploadProgressReader{
+		r:         inFile,
+		chunkSize: chunkSize,
+		onChunk: func(sentSoFar int64) {
+			if progress != nil {
+				progress(offset+sentSoFar, totalSize)
 			}
+		},
+	}
 
-			// Create the body io.Reader (read side of pipe) and the writer
-			// into which to write the application/octet-stream data.
-			body, writer := io.Pipe()
+	var reader io.Reader = counter
+	if hasher != nil {
+		reader = io.TeeReader(counter, hasher)
+	}
 
-			// Startup the streamer
-			var streamErr *error
-			go streamFile(inFile, imagePath, writer, "file", &streamErr)
+	body, writer := io.Pipe()
+	go func() {
+		defer inFile.Close()
+		streamImageFile(reader, writer)
+	}()
 
-			// Run the PUT request. The body will receive the octet-stream
-			// from the streamer.
+	headers := map[string]string{
+		"X-Auth-Token": gsp.access.AuthToken(),
+	}
+	if offset > 0 {
+		headers["Content-Range"] = fmt.Sprintf("bytes %d-%d/%d", offset, totalSize-1, totalSize)
+	}
+
+	response, err := gsp.context.ResponseWithReauth(gsp.access,
+		func() (*perigee.Response, error) {
+			url := gsp.endpoint + "/images/" + imageId + "/file"
 			return perigee.Request("PUT", url, perigee.Options{
 				ReqBody:       body,
 				CustomClient:  gsp.context.httpClient,
 				ContentType:   "application/octet-stream",
-				ContentLength: fileSize,
+				ContentLength: totalSize - offset,
+				MoreHeaders:   headers,
+				OkCodes:       []int{204},
+			})
+		})
+
+	if response != nil && response.HttpResponse != nil {
+		*acceptsRanges = strings.EqualFold(response.HttpResponse.Header.Get("Accept-Ranges"), "bytes")
+	}
+
+	return response, counter.read, err
+}
+
+// DownloadImageFile retrieves the raw octet-stream for the image identified
+// by imageId and copies it into dst as it is received, without buffering the
+// whole image in memory. It returns the number of bytes copied.
+func (gsp *genericServersProvider) DownloadImageFile(imageId string, dst io.Writer) (int64, error) {
+	var written int64
+
+	_, err := gsp.context.ResponseWithReauth(gsp.access,
+		func() (*perigee.Response, error) {
+			url := gsp.endpoint + "/images/" + imageId + "/file"
+
+			response, err := perigee.Request("GET", url, perigee.Options{
+				CustomClient: gsp.context.httpClient,
 				MoreHeaders: map[string]string{
 					"X-Auth-Token": gsp.access.AuthToken(),
+					"Accept":       "application/octet-stream",
 				},
-				OkCodes: []int{204},
+				OkCodes: []int{200, 204},
 			})
+			if err != nil {
+				return response, err
+			}
+			defer response.HttpResponse.Body.Close()
+
+			written, err = io.Copy(dst, response.HttpResponse.Body)
+			if err != nil {
+				return response, err
+			}
+
+			if cl := response.HttpResponse.ContentLength; cl >= 0 && written != cl {
+				return response, fmt.Errorf("image download truncated: got %d bytes, expected %d (Content-Length)", written, cl)
+			}
+
+			return response, nil
 		})
 
-	return err
+	return written, err
+}
+
+// DownloadImageFileToPath is a convenience wrapper around DownloadImageFile
+// that writes the image bits to the file at imagePath, creating or
+// truncating it as needed.
+func (gsp *genericServersProvider) DownloadImageFileToPath(imageId string, imagePath string) (int64, error) {
+	outFile, err := os.Create(imagePath)
+	if err != nil {
+		return 0, err
+	}
+	defer outFile.Close()
+
+	return gsp.DownloadImageFile(imageId, outFile)
+}
+
+// ErrImageSignatureMismatch is returned by VerifyImageSignature when an
+// image's img_signature property does not verify against the supplied
+// certificate.
+var ErrImageSignatureMismatch = errors.New("gophercloud: image signature verification failed")
+
+// hashMethodName renders hashFunc the way Glance's img_signature_hash_method
+// property expects it, e.g. crypto.SHA256 -> "SHA-256".
+func hashMethodName(hashFunc crypto.Hash) string {
+	switch hashFunc {
+	case crypto.SHA256:
+		return "SHA-256"
+	case crypto.SHA384:
+		return "SHA-384"
+	case crypto.SHA512:
+		return "SHA-512"
+	default:
+		return strings.ToUpper(hashFunc.String())
+	}
+}
+
+// hashFuncFromMethodName is the inverse of hashMethodName, used when
+// verifying a signature against the hash method an image reports.
+func hashFuncFromMethodName(name string) (crypto.Hash, error) {
+	switch name {
+	case "SHA-256":
+		return crypto.SHA256, nil
+	case "SHA-384":
+		return crypto.SHA384, nil
+	case "SHA-512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("gophercloud: unsupported image signature hash method %q", name)
+	}
+}
+
+// ecdsaCurveKeyType maps an ECDSA curve to the img_signature_key_type value
+// Glance expects for it.
+func ecdsaCurveKeyType(curve elliptic.Curve) string {
+	switch curve {
+	case elliptic.P256():
+		return "ECC_SECP256R1"
+	case elliptic.P384():
+		return "ECC_SECP384R1"
+	case elliptic.P521():
+		return "ECC_SECP521R1"
+	default:
+		return "ECC_SECP384R1"
+	}
+}
+
+// SignImageFile computes the hashFunc digest of the file at path and signs
+// it with key, returning an ImageSignature ready to attach to a NewImage.
+// The caller must still set CertificateUUID on the result to the Barbican
+// secret holding the certificate that verifies this signature.
+func SignImageFile(path string, key crypto.Signer, hashFunc crypto.Hash) (*ImageSignature, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := hashFunc.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	digest := h.Sum(nil)
+
+	var opts crypto.SignerOpts = hashFunc
+	keyType := ""
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		opts = &rsa.PSSOptions{Hash: hashFunc, SaltLength: rsa.PSSSaltLengthEqualsHash}
+		keyType = "RSA-PSS"
+	case *ecdsa.PrivateKey:
+		keyType = ecdsaCurveKeyType(k.Curve)
+	}
+
+	signature, err := key.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageSignature{
+		Signature:  base64.StdEncoding.EncodeToString(signature),
+		HashMethod: hashMethodName(hashFunc),
+		KeyType:    keyType,
+	}, nil
+}
+
+// VerifyImageSignature fetches the image identified by imageId, streams
+// its file content while recomputing the hash its img_signature_hash_method
+// property names, and verifies the resulting digest against
+// img_signature using the public key in certPEM. It returns
+// ErrImageSignatureMismatch (wrapped with details) if verification fails.
+func (gsp *genericServersProvider) VerifyImageSignature(imageId string, certPEM []byte) error {
+	image, err := gsp.ImageById(imageId)
+	if err != nil {
+		return err
+	}
+	if image == nil {
+		return fmt.Errorf("gophercloud: image %s not found", imageId)
+	}
+
+	sigB64, _ := image.Properties["img_signature"].(string)
+	hashMethod, _ := image.Properties["img_signature_hash_method"].(string)
+	if sigB64 == "" || hashMethod == "" {
+		return fmt.Errorf("gophercloud: image %s has no signature properties to verify", imageId)
+	}
+
+	hashFunc, err := hashFuncFromMethodName(hashMethod)
+	if err != nil {
+		return err
+	}
+
+	h := hashFunc.New()
+	if _, err := gsp.DownloadImageFile(imageId, h); err != nil {
+		return err
+	}
+
+	return verifyImageDigestSignature(h.Sum(nil), sigB64, hashFunc, certPEM)
+}
+
+// verifyImageDigestSignature checks digest (already hashed with hashFunc)
+// against the base64-encoded sigB64 using the public key found in the PEM
+// certificate certPEM. It returns ErrImageSignatureMismatch (wrapped with
+// details) if the signature doesn't verify.
+func verifyImageDigestSignature(digest []byte, sigB64 string, hashFunc crypto.Hash, certPEM []byte) error {
+	signature, err := base64.StdEncoding.DecodeString(sigB64)
+	if err != nil {
+		return fmt.Errorf("gophercloud: decoding image signature: %v", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return errors.New("gophercloud: no PEM certificate found in certPEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		opts := &rsa.PSSOptions{Hash: hashFunc, SaltLength: rsa.PSSSaltLengthAuto}
+		if err := rsa.VerifyPSS(pub, hashFunc, digest, signature, opts); err != nil {
+			return fmt.Errorf("%w: %v", ErrImageSignatureMismatch, err)
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return ErrImageSignatureMismatch
+		}
+	default:
+		return fmt.Errorf("gophercloud: unsupported image signature public key type %T", pub)
+	}
+
+	return nil
 }
 
 func (gsp *genericServersProvider) DeleteImageById(id string) error {
@@ -219,6 +1035,43 @@ type Image struct {
 	Status          string `json:"status"`
 	Updated         string `json:"updated"`
 	OsDcfDiskConfig string `json:"OS-DCF:diskConfig"`
+
+	// Properties holds any field present in the server's JSON response that
+	// is not otherwise captured above, e.g. Glance v2 attributes like
+	// visibility, tags, checksum, or vendor-specific custom properties. It
+	// is populated by UnmarshalJSON and ignored on marshal.
+	Properties map[string]interface{} `json:"-"`
+}
+
+// imageKnownFields lists the JSON keys already captured by named fields on
+// Image; everything else falls through to Properties.
+var imageKnownFields = []string{
+	"created", "id", "links", "minDisk", "minRam", "name", "progress",
+	"status", "updated", "OS-DCF:diskConfig",
+}
+
+// UnmarshalJSON decodes the named Image fields as usual, then stashes
+// whatever is left over into Properties so callers can read back fields
+// this package doesn't know about yet.
+func (img *Image) UnmarshalJSON(data []byte) error {
+	type imageAlias Image
+
+	var alias imageAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for _, known := range imageKnownFields {
+		delete(raw, known)
+	}
+
+	*img = Image(alias)
+	img.Properties = raw
+	return nil
 }
 
 // NewImage structures are used to create (upload) images.
@@ -261,4 +1114,43 @@ type NewImage struct {
 	ContainerFormat string   `json:"container_format"`
 	DiskFormat      string   `json:"disk_format"`
 	Tags            []string `json:"tags,omitempty"`
+
+	// Signature, if set, is emitted as the four img_signature* properties
+	// Glance and Nova/Cinder use to validate a trusted image before boot.
+	// Use SignImageFile to produce one, filling in CertificateUUID with
+	// the Barbican secret holding the matching certificate.
+	Signature *ImageSignature `json:"-"`
+}
+
+// ImageSignature carries the img_signature* properties Glance attaches to
+// an image so Nova/Cinder can validate its contents against a
+// Barbican-hosted certificate before booting from it.
+type ImageSignature struct {
+	// Signature is the base64-encoded signature blob, as produced by
+	// SignImageFile.
+	Signature string
+
+	// HashMethod is the hash algorithm the signature was computed over,
+	// e.g. "SHA-256".
+	HashMethod string
+
+	// KeyType identifies the signing key's algorithm, e.g. "RSA-PSS" or
+	// "ECC_SECP384R1".
+	KeyType string
+
+	// CertificateUUID is the Barbican secret UUID holding the
+	// certificate whose public key verifies this signature.
+	CertificateUUID string
+}
+
+// imageSignatureProperties returns sig rendered as the four Glance
+// img_signature* properties, ready to merge into an image create/update
+// body.
+func imageSignatureProperties(sig *ImageSignature) map[string]interface{} {
+	return map[string]interface{}{
+		"img_signature":                  sig.Signature,
+		"img_signature_hash_method":      sig.HashMethod,
+		"img_signature_key_type":         sig.KeyType,
+		"img_signature_certificate_uuid": sig.CertificateUUID,
+	}
 }
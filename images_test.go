@@ -0,0 +1,221 @@
+package gophercloud
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// TestResolveNextPageURL covers the "next" link shapes EachImagePage has to
+// follow while paginating: a bare path relative to the endpoint, a path that
+// already carries a query string, and an absolute URL (which Glance doesn't
+// normally send, but which ResolveReference must still handle correctly).
+func TestResolveNextPageURL(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint string
+		next     string
+		want     string
+	}{
+		{
+			name:     "relative path",
+			endpoint: "https://glance.example.com/v2/images",
+			next:     "/v2/images?marker=abc",
+			want:     "https://glance.example.com/v2/images?marker=abc",
+		},
+		{
+			name:     "relative path preserves query",
+			endpoint: "https://glance.example.com/v2/images?limit=50",
+			next:     "/v2/images?marker=abc&limit=50",
+			want:     "https://glance.example.com/v2/images?marker=abc&limit=50",
+		},
+		{
+			name:     "absolute URL",
+			endpoint: "https://glance.example.com/v2/images",
+			next:     "https://other.example.com/v2/images?marker=abc",
+			want:     "https://other.example.com/v2/images?marker=abc",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := resolveNextPageURL(c.endpoint, c.next)
+			if err != nil {
+				t.Fatalf("resolveNextPageURL(%q, %q) returned error: %v", c.endpoint, c.next, err)
+			}
+			if got != c.want {
+				t.Errorf("resolveNextPageURL(%q, %q) = %q, want %q", c.endpoint, c.next, got, c.want)
+			}
+		})
+	}
+}
+
+func TestResolveNextPageURLInvalidNext(t *testing.T) {
+	if _, err := resolveNextPageURL("https://glance.example.com", "://bad"); err == nil {
+		t.Fatal("expected an error for a malformed next link, got nil")
+	}
+}
+
+// TestVerifyUploadChecksum covers the match, mismatch, and missing-field
+// paths UploadImageFile relies on to confirm a completed upload.
+func TestVerifyUploadChecksum(t *testing.T) {
+	t.Run("match", func(t *testing.T) {
+		props := map[string]interface{}{"os_hash_value": "ABCDEF"}
+		if err := verifyUploadChecksum(props, "os_hash_value", ChecksumSHA256, "abcdef"); err != nil {
+			t.Errorf("expected a case-insensitive match to succeed, got %v", err)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		props := map[string]interface{}{"os_hash_value": "abcdef"}
+		err := verifyUploadChecksum(props, "os_hash_value", ChecksumSHA256, "000000")
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("expected ErrChecksumMismatch, got %v", err)
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		props := map[string]interface{}{}
+		if err := verifyUploadChecksum(props, "os_hash_value", ChecksumSHA256, "abcdef"); err == nil {
+			t.Error("expected an error when the remote field is absent, got nil")
+		}
+	})
+}
+
+func TestOsHashAlgoToHash(t *testing.T) {
+	cases := map[string]crypto.Hash{
+		"sha256": crypto.SHA256,
+		"sha512": crypto.SHA512,
+		"sha1":   crypto.SHA1,
+	}
+	for name, want := range cases {
+		got, err := osHashAlgoToHash(name)
+		if err != nil {
+			t.Errorf("osHashAlgoToHash(%q) returned error: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("osHashAlgoToHash(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if _, err := osHashAlgoToHash("md4"); err == nil {
+		t.Error("expected an error for an unsupported os_hash_algo, got nil")
+	}
+}
+
+func TestHashMethodNameRoundTrip(t *testing.T) {
+	for _, hashFunc := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		name := hashMethodName(hashFunc)
+		got, err := hashFuncFromMethodName(name)
+		if err != nil {
+			t.Errorf("hashFuncFromMethodName(%q) returned error: %v", name, err)
+		}
+		if got != hashFunc {
+			t.Errorf("hashFuncFromMethodName(hashMethodName(%v)) = %v, want %v", hashFunc, got, hashFunc)
+		}
+	}
+
+	if _, err := hashFuncFromMethodName("SHA-1"); err == nil {
+		t.Error("expected an error for an unsupported image signature hash method, got nil")
+	}
+}
+
+// selfSignedCert builds a minimal self-signed certificate around pub, for
+// use as the certPEM argument to verifyImageDigestSignature.
+func selfSignedCert(t *testing.T, pub crypto.PublicKey, signer crypto.Signer) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gophercloud-test"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, signer)
+	if err != nil {
+		t.Fatalf("creating self-signed certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// TestVerifyImageDigestSignatureRSA covers the successful verify and
+// tampered-digest paths for an RSA-PSS signed image.
+func TestVerifyImageDigestSignatureRSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	certPEM := selfSignedCert(t, &key.PublicKey, key)
+
+	digest := []byte("deterministic test digest padded to 32 bytes!!")[:32]
+	opts := &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash}
+	sig, err := key.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		t.Fatalf("signing digest: %v", err)
+	}
+	sigB64 := base64.StdEncoding.EncodeToString(sig)
+
+	if err := verifyImageDigestSignature(digest, sigB64, crypto.SHA256, certPEM); err != nil {
+		t.Errorf("expected a valid RSA signature to verify, got %v", err)
+	}
+
+	tampered := append([]byte(nil), digest...)
+	tampered[0] ^= 0xFF
+	err = verifyImageDigestSignature(tampered, sigB64, crypto.SHA256, certPEM)
+	if !errors.Is(err, ErrImageSignatureMismatch) {
+		t.Errorf("expected ErrImageSignatureMismatch for a tampered digest, got %v", err)
+	}
+}
+
+// TestVerifyImageDigestSignatureECDSA covers the successful verify and
+// tampered-digest paths for an ECDSA signed image.
+func TestVerifyImageDigestSignatureECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating ECDSA key: %v", err)
+	}
+	certPEM := selfSignedCert(t, &key.PublicKey, key)
+
+	digest := []byte("deterministic test digest padded to 32 bytes!!")[:32]
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest)
+	if err != nil {
+		t.Fatalf("signing digest: %v", err)
+	}
+	sigB64 := base64.StdEncoding.EncodeToString(sig)
+
+	if err := verifyImageDigestSignature(digest, sigB64, crypto.SHA256, certPEM); err != nil {
+		t.Errorf("expected a valid ECDSA signature to verify, got %v", err)
+	}
+
+	tampered := append([]byte(nil), digest...)
+	tampered[0] ^= 0xFF
+	err = verifyImageDigestSignature(tampered, sigB64, crypto.SHA256, certPEM)
+	if !errors.Is(err, ErrImageSignatureMismatch) {
+		t.Errorf("expected ErrImageSignatureMismatch for a tampered digest, got %v", err)
+	}
+}
+
+func TestVerifyImageDigestSignatureMalformedInputs(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating ECDSA key: %v", err)
+	}
+	certPEM := selfSignedCert(t, &key.PublicKey, key)
+	digest := make([]byte, 32)
+
+	if err := verifyImageDigestSignature(digest, "not-base64!!", crypto.SHA256, certPEM); err == nil {
+		t.Error("expected an error for a non-base64 signature, got nil")
+	}
+
+	sigB64 := base64.StdEncoding.EncodeToString([]byte("sig"))
+	if err := verifyImageDigestSignature(digest, sigB64, crypto.SHA256, []byte("not a cert")); err == nil {
+		t.Error("expected an error when certPEM has no PEM block, got nil")
+	}
+}